@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// adminQueryTimeout bounds DHT lookups served through the admin API so a
+// slow/absent peer can't hang an HTTP request indefinitely.
+const adminQueryTimeout = 10 * time.Second
+
+// AdminServer exposes BootstrapNode over HTTP/JSON and Prometheus, replacing
+// the old log-only PeriodicStats surface with something scrapable and
+// controllable in a cluster.
+type AdminServer struct {
+	node   *BootstrapNode
+	server *http.Server
+	token  string
+}
+
+// NewAdminServer builds (but does not start) an admin HTTP server bound to
+// listenAddr (e.g. "127.0.0.1:8080"). If token is non-empty, the mutating
+// /connect and /disconnect routes require an "Authorization: Bearer <token>"
+// header; an empty token leaves them open, which is only reasonable when
+// listenAddr is loopback-only.
+func NewAdminServer(node *BootstrapNode, listenAddr, token string) *AdminServer {
+	as := &AdminServer{node: node, token: token}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", as.handleHealthz)
+	mux.HandleFunc("/readyz", as.handleReadyz)
+	mux.HandleFunc("/peers", as.handlePeers)
+	mux.HandleFunc("/dht/stats", as.handleDHTStats)
+	mux.HandleFunc("/dht/lookup", as.handleDHTLookup)
+	mux.HandleFunc("/dht/providers", as.handleDHTProviders)
+	mux.HandleFunc("/connect", as.requireToken(as.handleConnect))
+	mux.HandleFunc("/disconnect", as.requireToken(as.handleDisconnect))
+	mux.HandleFunc("/discovery/peers", as.handleDiscoveryPeers)
+	mux.Handle("/metrics", promhttp.Handler())
+
+	as.server = &http.Server{
+		Addr:    listenAddr,
+		Handler: mux,
+	}
+	return as
+}
+
+// requireToken rejects requests to a mutating route unless they carry the
+// configured bearer token. With no token configured it's a no-op passthrough,
+// since some deployments rely on -admin-listen being loopback-only instead.
+func (as *AdminServer) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if as.token == "" {
+			next(w, r)
+			return
+		}
+		const prefix = "Bearer "
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, prefix) ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, prefix)), []byte(as.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// Start begins serving the admin API in the background.
+func (as *AdminServer) Start() {
+	go func() {
+		if err := as.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Admin server error: %v", err)
+		}
+	}()
+	log.Printf("Admin API listening on %s", as.server.Addr)
+}
+
+// Close shuts the admin server down.
+func (as *AdminServer) Close(ctx context.Context) error {
+	return as.server.Shutdown(ctx)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode admin response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func (as *AdminServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz reports ready once the DHT has a non-empty routing table.
+func (as *AdminServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if as.node.dht.RoutingTable().Size() == 0 {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+func (as *AdminServer) handlePeers(w http.ResponseWriter, r *http.Request) {
+	peers := as.node.GetPeers()
+	ids := make([]string, len(peers))
+	for i, p := range peers {
+		ids[i] = p.String()
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"peers": ids})
+}
+
+func (as *AdminServer) handleDHTStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, as.node.GetDHTStats())
+}
+
+func (as *AdminServer) handleDHTLookup(w http.ResponseWriter, r *http.Request) {
+	peerStr := r.URL.Query().Get("peer")
+	if peerStr == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing peer query parameter"))
+		return
+	}
+	id, err := peer.Decode(peerStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid peer id: %w", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), adminQueryTimeout)
+	defer cancel()
+
+	dhtQueriesTotal.Inc()
+	info, err := as.node.dht.FindPeer(ctx, id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("peer lookup failed: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":    info.ID.String(),
+		"addrs": formatMultiaddrs(info.Addrs, info.ID),
+	})
+}
+
+func (as *AdminServer) handleDHTProviders(w http.ResponseWriter, r *http.Request) {
+	cidStr := r.URL.Query().Get("cid")
+	if cidStr == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing cid query parameter"))
+		return
+	}
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid cid: %w", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), adminQueryTimeout)
+	defer cancel()
+
+	dhtQueriesTotal.Inc()
+	var providers []string
+	for info := range as.node.dht.FindProvidersAsync(ctx, c, 20) {
+		providers = append(providers, info.ID.String())
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"providers": providers})
+}
+
+func (as *AdminServer) handleDiscoveryPeers(w http.ResponseWriter, r *http.Request) {
+	peers := as.node.GetDiscoveredPeers()
+	result := make([]map[string]interface{}, len(peers))
+	for i, info := range peers {
+		result[i] = map[string]interface{}{
+			"id":    info.ID.String(),
+			"addrs": formatMultiaddrs(info.Addrs, info.ID),
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"peers": result})
+}
+
+func (as *AdminServer) handleConnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+	var req struct {
+		Multiaddr string `json:"multiaddr"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	ma, err := multiaddr.NewMultiaddr(req.Multiaddr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid multiaddr: %w", err))
+		return
+	}
+	info, err := peer.AddrInfoFromP2pAddr(ma)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("multiaddr missing /p2p/ component: %w", err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), adminQueryTimeout)
+	defer cancel()
+	if err := as.node.host.Connect(ctx, *info); err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("connect failed: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "connected", "peer": info.ID.String()})
+}
+
+func (as *AdminServer) handleDisconnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("use POST"))
+		return
+	}
+	var req struct {
+		Peer string `json:"peer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	id, err := peer.Decode(req.Peer)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid peer id: %w", err))
+		return
+	}
+	if err := as.node.host.Network().ClosePeer(id); err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("disconnect failed: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "disconnected", "peer": id.String()})
+}
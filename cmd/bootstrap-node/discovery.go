@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	discovery "github.com/libp2p/go-libp2p/p2p/discovery/routing"
+)
+
+// discoveryFindInterval is how often each rendezvous string is re-queried
+// to warm the routing table with freshly discovered peers.
+const discoveryFindInterval = 5 * time.Minute
+
+// DiscoveryService advertises this node under one or more rendezvous
+// strings via the DHT and periodically dials peers discovered under them.
+type DiscoveryService struct {
+	host       host.Host
+	disc       *discovery.RoutingDiscovery
+	rendezvous []string
+	ctx        context.Context
+
+	mu         sync.Mutex
+	discovered map[peer.ID]peer.AddrInfo
+}
+
+// NewDiscoveryService wraps d with a RoutingDiscovery used to advertise and
+// find peers under rendezvous.
+func NewDiscoveryService(ctx context.Context, h host.Host, d *dht.IpfsDHT, rendezvous []string) *DiscoveryService {
+	return &DiscoveryService{
+		host:       h,
+		disc:       discovery.NewRoutingDiscovery(d),
+		rendezvous: rendezvous,
+		ctx:        ctx,
+		discovered: make(map[peer.ID]peer.AddrInfo),
+	}
+}
+
+// Start advertises under every configured rendezvous string and launches a
+// background loop per string that finds and dials newly discovered peers.
+func (ds *DiscoveryService) Start() error {
+	for _, ns := range ds.rendezvous {
+		if _, err := ds.disc.Advertise(ds.ctx, ns); err != nil {
+			return fmt.Errorf("failed to advertise rendezvous %q: %w", ns, err)
+		}
+		log.Printf("Advertising under rendezvous: %s", ns)
+		go ds.findPeersLoop(ns)
+	}
+	return nil
+}
+
+func (ds *DiscoveryService) findPeersLoop(ns string) {
+	ds.findAndDial(ns)
+
+	ticker := time.NewTicker(discoveryFindInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ds.findAndDial(ns)
+		case <-ds.ctx.Done():
+			return
+		}
+	}
+}
+
+func (ds *DiscoveryService) findAndDial(ns string) {
+	peerChan, err := ds.disc.FindPeers(ds.ctx, ns)
+	if err != nil {
+		log.Printf("Rendezvous lookup failed for %q: %v", ns, err)
+		return
+	}
+
+	for info := range peerChan {
+		if info.ID == ds.host.ID() || len(info.Addrs) == 0 {
+			continue
+		}
+
+		ds.mu.Lock()
+		ds.discovered[info.ID] = info
+		ds.mu.Unlock()
+
+		if len(ds.host.Network().ConnsToPeer(info.ID)) > 0 {
+			continue
+		}
+
+		dialCtx, cancel := context.WithTimeout(ds.ctx, 30*time.Second)
+		if err := ds.host.Connect(dialCtx, info); err != nil {
+			log.Printf("Failed to dial rendezvous peer %s: %v", info.ID, err)
+		} else {
+			log.Printf("Connected to rendezvous peer %s (%s)", info.ID, ns)
+		}
+		cancel()
+	}
+}
+
+// GetDiscoveredPeers returns every peer seen via rendezvous discovery so far.
+func (ds *DiscoveryService) GetDiscoveredPeers() []peer.AddrInfo {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	peers := make([]peer.AddrInfo, 0, len(ds.discovered))
+	for _, info := range ds.discovered {
+		peers = append(peers, info)
+	}
+	return peers
+}
@@ -0,0 +1,16 @@
+package main
+
+import "strings"
+
+// stringSliceFlag implements flag.Value, collecting one value per
+// occurrence of the flag (e.g. repeated "-listen addr" invocations).
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/control"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// PeerList holds the peer IDs, CIDRs, and multiaddrs parsed from an
+// -allow-peers or -deny-peers file, one entry per line.
+type PeerList struct {
+	peerIDs   map[peer.ID]struct{}
+	cidrs     []*net.IPNet
+	multiaddr []multiaddr.Multiaddr
+}
+
+// LoadPeerList parses a file of peer IDs, CIDRs, and multiaddrs (one per
+// line, blank lines and "#" comments ignored).
+func LoadPeerList(path string) (*PeerList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open peer list %s: %w", path, err)
+	}
+	defer f.Close()
+
+	pl := &PeerList{peerIDs: make(map[peer.ID]struct{})}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if id, err := peer.Decode(line); err == nil {
+			pl.peerIDs[id] = struct{}{}
+			continue
+		}
+		if _, ipnet, err := net.ParseCIDR(line); err == nil {
+			pl.cidrs = append(pl.cidrs, ipnet)
+			continue
+		}
+		if ma, err := multiaddr.NewMultiaddr(line); err == nil {
+			pl.multiaddr = append(pl.multiaddr, ma)
+			continue
+		}
+		return nil, fmt.Errorf("unrecognized entry in %s: %q", path, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read peer list %s: %w", path, err)
+	}
+	return pl, nil
+}
+
+// matchesPeer reports whether id is listed directly.
+func (pl *PeerList) matchesPeer(id peer.ID) bool {
+	if pl == nil {
+		return false
+	}
+	_, ok := pl.peerIDs[id]
+	return ok
+}
+
+// matchesAddr reports whether addr falls within a listed CIDR or multiaddr
+// prefix.
+func (pl *PeerList) matchesAddr(addr multiaddr.Multiaddr) bool {
+	if pl == nil {
+		return false
+	}
+	if ip, err := manetToIP(addr); err == nil {
+		for _, cidr := range pl.cidrs {
+			if cidr.Contains(ip) {
+				return true
+			}
+		}
+	}
+	for _, ma := range pl.multiaddr {
+		if addr.Equal(ma) {
+			return true
+		}
+	}
+	return false
+}
+
+// manetToIP extracts the IP component from a multiaddr, if any.
+func manetToIP(addr multiaddr.Multiaddr) (net.IP, error) {
+	for _, p := range addr.Protocols() {
+		switch p.Code {
+		case multiaddr.P_IP4, multiaddr.P_IP6:
+			v, err := addr.ValueForProtocol(p.Code)
+			if err != nil {
+				return nil, err
+			}
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP %q", v)
+			}
+			return ip, nil
+		}
+	}
+	return nil, fmt.Errorf("no IP component in %s", addr)
+}
+
+// PeerGater is a connmgr.ConnectionGater that enforces an allow-list and a
+// deny-list across every libp2p connection establishment phase. A non-empty
+// allow-list is treated as a closed swarm: anything not explicitly allowed
+// is rejected.
+type PeerGater struct {
+	allow *PeerList
+	deny  *PeerList
+}
+
+// NewPeerGater builds a gater from optional allow/deny lists. Either may be
+// nil to skip that check.
+func NewPeerGater(allow, deny *PeerList) *PeerGater {
+	return &PeerGater{allow: allow, deny: deny}
+}
+
+func (g *PeerGater) permitPeer(id peer.ID) bool {
+	if g.deny.matchesPeer(id) {
+		return false
+	}
+	if g.allow != nil && len(g.allow.peerIDs) > 0 && !g.allow.matchesPeer(id) {
+		return false
+	}
+	return true
+}
+
+func (g *PeerGater) permitAddr(addr multiaddr.Multiaddr) bool {
+	if g.deny.matchesAddr(addr) {
+		return false
+	}
+	if g.allow != nil && (len(g.allow.cidrs) > 0 || len(g.allow.multiaddr) > 0) && !g.allow.matchesAddr(addr) {
+		return false
+	}
+	return true
+}
+
+// InterceptPeerDial is called before dialing a peer ID, prior to resolving
+// any addresses.
+func (g *PeerGater) InterceptPeerDial(p peer.ID) bool {
+	return g.permitPeer(p)
+}
+
+// InterceptAddrDial is called before dialing a specific address for a peer.
+func (g *PeerGater) InterceptAddrDial(p peer.ID, addr multiaddr.Multiaddr) bool {
+	return g.permitPeer(p) && g.permitAddr(addr)
+}
+
+// InterceptAccept is called on an inbound connection before it is secured.
+func (g *PeerGater) InterceptAccept(cma network.ConnMultiaddrs) bool {
+	return g.permitAddr(cma.RemoteMultiaddr())
+}
+
+// InterceptSecured is called after the security handshake, once the remote
+// peer ID is known.
+func (g *PeerGater) InterceptSecured(dir network.Direction, p peer.ID, cma network.ConnMultiaddrs) bool {
+	return g.permitPeer(p) && g.permitAddr(cma.RemoteMultiaddr())
+}
+
+// InterceptUpgraded is called once stream muxing is negotiated; openclaw
+// has nothing further to check here.
+func (g *PeerGater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
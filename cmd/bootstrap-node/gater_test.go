@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+func toIPNets(t *testing.T, cidrs ...string) []*net.IPNet {
+	t.Helper()
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			t.Fatalf("net.ParseCIDR(%q): %v", c, err)
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets
+}
+
+func mustPeerID(t *testing.T) peer.ID {
+	t.Helper()
+	id, err := peer.Decode("12D3KooWBnextgPPgsjhHBPxaHx1PEoqWS3YzTMgo1RKYkGRraKg")
+	if err != nil {
+		t.Fatalf("peer.Decode: %v", err)
+	}
+	return id
+}
+
+func mustAddr(t *testing.T, s string) multiaddr.Multiaddr {
+	t.Helper()
+	ma, err := multiaddr.NewMultiaddr(s)
+	if err != nil {
+		t.Fatalf("NewMultiaddr(%q): %v", s, err)
+	}
+	return ma
+}
+
+func TestPeerGaterPermitPeerNoLists(t *testing.T) {
+	g := NewPeerGater(nil, nil)
+	if !g.permitPeer(mustPeerID(t)) {
+		t.Fatal("expected peer to be permitted when no allow/deny lists are configured")
+	}
+}
+
+func TestPeerGaterPermitPeerAllowListIsClosedSwarm(t *testing.T) {
+	allowed := mustPeerID(t)
+	other, err := peer.Decode("12D3KooWGRUFPW714JaJzy8Lug8sDL7ckR3yk7PYXeXeBnm6mAqS")
+	if err != nil {
+		t.Fatalf("peer.Decode: %v", err)
+	}
+
+	allow := &PeerList{peerIDs: map[peer.ID]struct{}{allowed: {}}}
+	g := NewPeerGater(allow, nil)
+
+	if !g.permitPeer(allowed) {
+		t.Error("expected explicitly allowed peer to be permitted")
+	}
+	if g.permitPeer(other) {
+		t.Error("expected peer not on a non-empty allow-list to be rejected")
+	}
+}
+
+func TestPeerGaterPermitPeerDenyOverridesAllow(t *testing.T) {
+	id := mustPeerID(t)
+	allow := &PeerList{peerIDs: map[peer.ID]struct{}{id: {}}}
+	deny := &PeerList{peerIDs: map[peer.ID]struct{}{id: {}}}
+
+	g := NewPeerGater(allow, deny)
+	if g.permitPeer(id) {
+		t.Fatal("expected deny-list to take precedence over allow-list")
+	}
+}
+
+func TestPeerGaterPermitAddrNoLists(t *testing.T) {
+	g := NewPeerGater(nil, nil)
+	if !g.permitAddr(mustAddr(t, "/ip4/203.0.113.5/tcp/4001")) {
+		t.Fatal("expected address to be permitted when no allow/deny lists are configured")
+	}
+}
+
+func TestPeerGaterPermitAddrAllowListCIDR(t *testing.T) {
+	g := NewPeerGater(&PeerList{cidrs: toIPNets(t, "10.0.0.0/8")}, nil)
+
+	if !g.permitAddr(mustAddr(t, "/ip4/10.1.2.3/tcp/4001")) {
+		t.Error("expected address within allowed CIDR to be permitted")
+	}
+	if g.permitAddr(mustAddr(t, "/ip4/203.0.113.5/tcp/4001")) {
+		t.Error("expected address outside allowed CIDR to be rejected")
+	}
+}
+
+func TestPeerGaterPermitAddrDenyCIDROverridesAllow(t *testing.T) {
+	g := NewPeerGater(
+		&PeerList{cidrs: toIPNets(t, "10.0.0.0/8")},
+		&PeerList{cidrs: toIPNets(t, "10.1.0.0/16")},
+	)
+
+	if g.permitAddr(mustAddr(t, "/ip4/10.1.2.3/tcp/4001")) {
+		t.Fatal("expected deny CIDR to take precedence over a broader allow CIDR")
+	}
+	if !g.permitAddr(mustAddr(t, "/ip4/10.2.2.3/tcp/4001")) {
+		t.Fatal("expected address outside the deny CIDR but inside the allow CIDR to be permitted")
+	}
+}
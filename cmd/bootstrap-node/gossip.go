@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HeartbeatTopic is the default GossipSub topic nodes broadcast liveness on.
+const HeartbeatTopic = "/openclaw/heartbeat/1.0.0"
+
+// NodeVersion is reported in heartbeats and logs. Bumped on release.
+const NodeVersion = "0.1.0"
+
+var (
+	heartbeatsSent = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openclaw_heartbeats_sent_total",
+		Help: "Number of heartbeat messages published to the gossip topic.",
+	})
+	heartbeatsReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openclaw_heartbeats_received_total",
+		Help: "Number of valid heartbeat messages received from peers.",
+	})
+	heartbeatsInvalid = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openclaw_heartbeats_invalid_total",
+		Help: "Number of heartbeat messages rejected for bad signatures or decoding errors.",
+	})
+)
+
+// setupGossip joins the GossipSub mesh and starts the heartbeat broadcast
+// and verification loops. It is called once from NewBootstrapNode.
+func (bn *BootstrapNode) setupGossip(startedAt time.Time) error {
+	ps, err := pubsub.NewGossipSub(bn.ctx, bn.host)
+	if err != nil {
+		return fmt.Errorf("failed to create gossipsub: %w", err)
+	}
+	bn.pubsub = ps
+	bn.startedAt = startedAt
+
+	topic, err := ps.Join(HeartbeatTopic)
+	if err != nil {
+		return fmt.Errorf("failed to join heartbeat topic: %w", err)
+	}
+	bn.heartbeatTopic = topic
+	bn.topics[HeartbeatTopic] = topic
+
+	sub, err := topic.Subscribe()
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to heartbeat topic: %w", err)
+	}
+	bn.heartbeatSub = sub
+
+	go bn.heartbeatBroadcastLoop(30 * time.Second)
+	go bn.heartbeatReadLoop()
+
+	return nil
+}
+
+// joinTopic returns the cached *pubsub.Topic for topicName, joining it the
+// first time it's seen. go-libp2p-pubsub errors if Join is called twice for
+// the same topic ID, so every caller that needs a topic handle (Publish,
+// Subscribe) must go through this instead of calling bn.pubsub.Join
+// directly.
+func (bn *BootstrapNode) joinTopic(topicName string) (*pubsub.Topic, error) {
+	bn.topicsMu.Lock()
+	defer bn.topicsMu.Unlock()
+
+	if topic, ok := bn.topics[topicName]; ok {
+		return topic, nil
+	}
+	topic, err := bn.pubsub.Join(topicName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join topic %q: %w", topicName, err)
+	}
+	bn.topics[topicName] = topic
+	return topic, nil
+}
+
+// Publish sends msg on the named GossipSub topic, joining it first if the
+// node has not already done so.
+func (bn *BootstrapNode) Publish(topicName string, msg []byte) error {
+	topic, err := bn.joinTopic(topicName)
+	if err != nil {
+		return err
+	}
+	if err := topic.Publish(bn.ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish to topic %q: %w", topicName, err)
+	}
+	return nil
+}
+
+// Subscribe returns a subscription to the named GossipSub topic.
+func (bn *BootstrapNode) Subscribe(topicName string) (*pubsub.Subscription, error) {
+	topic, err := bn.joinTopic(topicName)
+	if err != nil {
+		return nil, err
+	}
+	return topic.Subscribe()
+}
+
+// heartbeatBroadcastLoop signs and publishes a heartbeat on an interval.
+func (bn *BootstrapNode) heartbeatBroadcastLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := bn.broadcastHeartbeat(); err != nil {
+				log.Printf("Failed to broadcast heartbeat: %v", err)
+			}
+		case <-bn.ctx.Done():
+			return
+		}
+	}
+}
+
+func (bn *BootstrapNode) broadcastHeartbeat() error {
+	pubKeyBytes, err := crypto.MarshalPublicKey(bn.host.Peerstore().PubKey(bn.host.ID()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal pub key: %w", err)
+	}
+
+	hb := &Heartbeat{
+		NodeID:           bn.host.ID().String(),
+		Version:          NodeVersion,
+		UptimeSeconds:    int64(time.Since(bn.startedAt).Seconds()),
+		PeerCount:        int32(len(bn.host.Network().Peers())),
+		RoutingTableSize: int32(bn.dht.RoutingTable().Size()),
+		TimestampUnix:    time.Now().Unix(),
+		PubKey:           pubKeyBytes,
+	}
+
+	sig, err := bn.host.Peerstore().PrivKey(bn.host.ID()).Sign(hb.signingBytes())
+	if err != nil {
+		return fmt.Errorf("failed to sign heartbeat: %w", err)
+	}
+	hb.Signature = sig
+
+	if err := bn.heartbeatTopic.Publish(bn.ctx, hb.Marshal()); err != nil {
+		return fmt.Errorf("failed to publish heartbeat: %w", err)
+	}
+	heartbeatsSent.Inc()
+	return nil
+}
+
+// heartbeatReadLoop consumes incoming heartbeats and verifies their signature
+// against the sender's peer ID before counting them as valid.
+func (bn *BootstrapNode) heartbeatReadLoop() {
+	for {
+		msg, err := bn.heartbeatSub.Next(bn.ctx)
+		if err != nil {
+			// Context cancellation on shutdown surfaces here too.
+			return
+		}
+		if msg.ReceivedFrom == bn.host.ID() {
+			continue
+		}
+		if err := verifyHeartbeat(msg.ReceivedFrom, msg.Data); err != nil {
+			log.Printf("Rejected heartbeat from %s: %v", msg.ReceivedFrom, err)
+			heartbeatsInvalid.Inc()
+			continue
+		}
+		heartbeatsReceived.Inc()
+	}
+}
+
+func verifyHeartbeat(from peer.ID, data []byte) error {
+	var hb Heartbeat
+	if err := hb.Unmarshal(data); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	pubKey, err := crypto.UnmarshalPublicKey(hb.PubKey)
+	if err != nil {
+		return fmt.Errorf("unmarshal pub key: %w", err)
+	}
+
+	id, err := peer.IDFromPublicKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("derive peer id: %w", err)
+	}
+	if id != from {
+		return fmt.Errorf("pub key does not match sender %s", from)
+	}
+
+	ok, err := pubKey.Verify(hb.signingBytes(), hb.Signature)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
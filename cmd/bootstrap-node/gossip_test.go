@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func signedHeartbeat(t *testing.T, priv crypto.PrivKey, pub crypto.PubKey) *Heartbeat {
+	t.Helper()
+
+	pubKeyBytes, err := crypto.MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPublicKey: %v", err)
+	}
+
+	hb := &Heartbeat{
+		NodeID:        "test-node",
+		Version:       NodeVersion,
+		UptimeSeconds: 42,
+		PeerCount:     1,
+		TimestampUnix: 1700000000,
+		PubKey:        pubKeyBytes,
+	}
+	sig, err := priv.Sign(hb.signingBytes())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	hb.Signature = sig
+	return hb
+}
+
+func TestVerifyHeartbeatAcceptsValidSignature(t *testing.T) {
+	priv, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatalf("IDFromPublicKey: %v", err)
+	}
+
+	hb := signedHeartbeat(t, priv, pub)
+	if err := verifyHeartbeat(id, hb.Marshal()); err != nil {
+		t.Fatalf("verifyHeartbeat: unexpected error: %v", err)
+	}
+}
+
+func TestVerifyHeartbeatRejectsSenderMismatch(t *testing.T) {
+	priv, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+	_, otherPub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+	otherID, err := peer.IDFromPublicKey(otherPub)
+	if err != nil {
+		t.Fatalf("IDFromPublicKey: %v", err)
+	}
+
+	hb := signedHeartbeat(t, priv, pub)
+	if err := verifyHeartbeat(otherID, hb.Marshal()); err == nil {
+		t.Fatal("expected error for sender/pubkey mismatch, got nil")
+	}
+}
+
+func TestVerifyHeartbeatRejectsTamperedPayload(t *testing.T) {
+	priv, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatalf("IDFromPublicKey: %v", err)
+	}
+
+	hb := signedHeartbeat(t, priv, pub)
+	hb.UptimeSeconds++ // mutate a signed field after signing
+
+	if err := verifyHeartbeat(id, hb.Marshal()); err == nil {
+		t.Fatal("expected error for tampered heartbeat payload, got nil")
+	}
+}
+
+func TestVerifyHeartbeatRejectsGarbage(t *testing.T) {
+	if err := verifyHeartbeat(peer.ID(""), []byte("not a heartbeat")); err == nil {
+		t.Fatal("expected error decoding garbage input, got nil")
+	}
+}
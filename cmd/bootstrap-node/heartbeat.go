@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Heartbeat is the liveness message gossiped on HeartbeatTopic. The original
+// request asked for a protobuf schema; this is a hand-rolled, order-dependent
+// length-prefixed encoding instead (see Marshal), because generating real
+// protobuf code requires protoc/protoc-gen-go and neither is available in
+// this build environment, and hand-writing "generated" code without being
+// able to run the generator isn't something we're willing to check in as if
+// it were the real thing. Flagging that gap explicitly rather than shipping
+// a schema-less ad hoc format silently: a real .proto + generated bindings
+// is still the right fix and should replace this once protoc tooling is
+// available. Until then, adding a field means adding it to signingBytes,
+// Marshal, and Unmarshal together and bumping NodeVersion for any
+// wire-incompatible change.
+type Heartbeat struct {
+	NodeID           string
+	Version          string
+	UptimeSeconds    int64
+	PeerCount        int32
+	RoutingTableSize int32
+	TimestampUnix    int64
+	PubKey           []byte
+	Signature        []byte
+}
+
+// signingBytes returns the canonical encoding of the heartbeat fields that
+// are covered by the signature, i.e. everything except Signature itself.
+func (h *Heartbeat) signingBytes() []byte {
+	buf := make([]byte, 0, 64+len(h.NodeID)+len(h.Version)+len(h.PubKey))
+	buf = appendString(buf, h.NodeID)
+	buf = appendString(buf, h.Version)
+	buf = appendInt64(buf, h.UptimeSeconds)
+	buf = appendInt32(buf, h.PeerCount)
+	buf = appendInt32(buf, h.RoutingTableSize)
+	buf = appendInt64(buf, h.TimestampUnix)
+	buf = appendBytes(buf, h.PubKey)
+	return buf
+}
+
+// Marshal encodes the heartbeat, including its signature, for transport.
+func (h *Heartbeat) Marshal() []byte {
+	buf := h.signingBytes()
+	buf = appendBytes(buf, h.Signature)
+	return buf
+}
+
+// Unmarshal decodes a heartbeat produced by Marshal.
+func (h *Heartbeat) Unmarshal(data []byte) error {
+	var err error
+	if h.NodeID, data, err = readString(data); err != nil {
+		return fmt.Errorf("node_id: %w", err)
+	}
+	if h.Version, data, err = readString(data); err != nil {
+		return fmt.Errorf("version: %w", err)
+	}
+	if h.UptimeSeconds, data, err = readInt64(data); err != nil {
+		return fmt.Errorf("uptime_seconds: %w", err)
+	}
+	if h.PeerCount, data, err = readInt32(data); err != nil {
+		return fmt.Errorf("peer_count: %w", err)
+	}
+	if h.RoutingTableSize, data, err = readInt32(data); err != nil {
+		return fmt.Errorf("routing_table_size: %w", err)
+	}
+	if h.TimestampUnix, data, err = readInt64(data); err != nil {
+		return fmt.Errorf("timestamp_unix: %w", err)
+	}
+	if h.PubKey, data, err = readBytes(data); err != nil {
+		return fmt.Errorf("pub_key: %w", err)
+	}
+	if h.Signature, data, err = readBytes(data); err != nil {
+		return fmt.Errorf("signature: %w", err)
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("unexpected trailing bytes: %d", len(data))
+	}
+	return nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	return appendBytes(buf, []byte(s))
+}
+
+func appendInt64(buf []byte, v int64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], uint64(v))
+	return append(buf, tmp[:]...)
+}
+
+func appendInt32(buf []byte, v int32) []byte {
+	var tmp [4]byte
+	binary.BigEndian.PutUint32(tmp[:], uint32(v))
+	return append(buf, tmp[:]...)
+}
+
+func appendBytes(buf []byte, b []byte) []byte {
+	var tmp [4]byte
+	binary.BigEndian.PutUint32(tmp[:], uint32(len(b)))
+	buf = append(buf, tmp[:]...)
+	return append(buf, b...)
+}
+
+func readString(data []byte) (string, []byte, error) {
+	b, rest, err := readBytes(data)
+	return string(b), rest, err
+}
+
+func readInt64(data []byte) (int64, []byte, error) {
+	if len(data) < 8 {
+		return 0, nil, fmt.Errorf("truncated int64")
+	}
+	return int64(binary.BigEndian.Uint64(data[:8])), data[8:], nil
+}
+
+func readInt32(data []byte) (int32, []byte, error) {
+	if len(data) < 4 {
+		return 0, nil, fmt.Errorf("truncated int32")
+	}
+	return int32(binary.BigEndian.Uint32(data[:4])), data[4:], nil
+}
+
+func readBytes(data []byte) ([]byte, []byte, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("truncated length prefix")
+	}
+	n := binary.BigEndian.Uint32(data[:4])
+	data = data[4:]
+	if uint32(len(data)) < n {
+		return nil, nil, fmt.Errorf("truncated payload: want %d have %d", n, len(data))
+	}
+	return data[:n], data[n:], nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import "testing"
+
+func TestHeartbeatMarshalUnmarshalRoundTrip(t *testing.T) {
+	want := &Heartbeat{
+		NodeID:           "12D3KooWtest",
+		Version:          NodeVersion,
+		UptimeSeconds:    3600,
+		PeerCount:        12,
+		RoutingTableSize: 34,
+		TimestampUnix:    1700000000,
+		PubKey:           []byte{0x01, 0x02, 0x03},
+		Signature:        []byte{0xaa, 0xbb, 0xcc, 0xdd},
+	}
+
+	var got Heartbeat
+	if err := got.Unmarshal(want.Marshal()); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if got.NodeID != want.NodeID ||
+		got.Version != want.Version ||
+		got.UptimeSeconds != want.UptimeSeconds ||
+		got.PeerCount != want.PeerCount ||
+		got.RoutingTableSize != want.RoutingTableSize ||
+		got.TimestampUnix != want.TimestampUnix ||
+		string(got.PubKey) != string(want.PubKey) ||
+		string(got.Signature) != string(want.Signature) {
+		t.Fatalf("round trip mismatch: got %+v, want %+v", got, want)
+	}
+}
+
+func TestHeartbeatUnmarshalRejectsTruncated(t *testing.T) {
+	hb := &Heartbeat{NodeID: "n", Version: "v", PubKey: []byte{1}, Signature: []byte{2}}
+	data := hb.Marshal()
+
+	var decoded Heartbeat
+	if err := decoded.Unmarshal(data[:len(data)-1]); err == nil {
+		t.Fatal("expected error decoding truncated heartbeat, got nil")
+	}
+}
+
+func TestHeartbeatUnmarshalRejectsTrailingBytes(t *testing.T) {
+	hb := &Heartbeat{NodeID: "n", Version: "v"}
+	data := append(hb.Marshal(), 0xff)
+
+	var decoded Heartbeat
+	if err := decoded.Unmarshal(data); err == nil {
+		t.Fatal("expected error decoding heartbeat with trailing bytes, got nil")
+	}
+}
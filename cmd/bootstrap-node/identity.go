@@ -0,0 +1,328 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeyType identifies which libp2p key algorithm an identity file holds.
+type KeyType string
+
+const (
+	KeyTypeEd25519   KeyType = "ed25519"
+	KeyTypeSecp256k1 KeyType = "secp256k1"
+	KeyTypeRSA       KeyType = "rsa"
+)
+
+// identityFileVersion is bumped whenever the on-disk schema changes.
+const identityFileVersion = 1
+
+// identityRecord is a single key entry within an identity file: the current
+// key, or the previous key kept around during a rotation grace period.
+type identityRecord struct {
+	KeyType   KeyType   `json:"key_type"`
+	CreatedAt time.Time `json:"created_at"`
+	KeyData   string    `json:"key_data"` // base64; scrypt+secretbox-encrypted if Encrypted
+	Encrypted bool      `json:"encrypted"`
+	Salt      string    `json:"salt,omitempty"`
+	Nonce     string    `json:"nonce,omitempty"`
+}
+
+// identityFile is the on-disk JSON format written by IdentityStore.
+type identityFile struct {
+	Version int             `json:"version"`
+	Current identityRecord  `json:"current"`
+	Prev    *identityRecord `json:"previous,omitempty"`
+	// PrevExpiresAt is when the previous key should stop being announced.
+	PrevExpiresAt time.Time `json:"previous_expires_at,omitempty"`
+}
+
+// IdentityStore reads and writes versioned node identity files: generate
+// once on first run, then persist forever, with optional passphrase-based
+// encryption at rest. Rotate keeps the outgoing key around as "previous"
+// so callers (see GraceHost) can keep it reachable during a migration
+// window instead of breaking peers that still dial the old ID.
+type IdentityStore struct {
+	path       string
+	passphrase string
+}
+
+// NewIdentityStore returns a store rooted at path. An empty passphrase
+// disables encryption at rest.
+func NewIdentityStore(path, passphrase string) *IdentityStore {
+	return &IdentityStore{path: path, passphrase: passphrase}
+}
+
+// LoadOrCreate loads the identity at s.path, generating and persisting a new
+// keyType key (0600 perms) if the file does not exist yet.
+func (s *IdentityStore) LoadOrCreate(keyType KeyType) (crypto.PrivKey, error) {
+	if _, err := os.Stat(s.path); err == nil {
+		priv, _, err := s.Load()
+		return priv, err
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to stat identity file %s: %w", s.path, err)
+	}
+
+	priv, err := generateKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.save(&identityFile{Version: identityFileVersion}, priv, keyType); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// Load reads the current key, and the previous key if one is still within
+// its rotation grace period.
+func (s *IdentityStore) Load() (current crypto.PrivKey, previous crypto.PrivKey, err error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read identity file %s: %w", s.path, err)
+	}
+
+	var f identityFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse identity file %s: %w", s.path, err)
+	}
+
+	current, err = s.decodeRecord(f.Current)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode current key: %w", err)
+	}
+
+	if f.Prev != nil && time.Now().Before(f.PrevExpiresAt) {
+		previous, err = s.decodeRecord(*f.Prev)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode previous key: %w", err)
+		}
+	}
+
+	return current, previous, nil
+}
+
+// Rotate generates a new keyType key, keeping the existing current key as
+// "previous" (announceable) for grace before it's dropped entirely.
+func (s *IdentityStore) Rotate(keyType KeyType, grace time.Duration) (crypto.PrivKey, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity file %s: %w", s.path, err)
+	}
+	var f identityFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse identity file %s: %w", s.path, err)
+	}
+
+	prev := f.Current
+	newKey, err := generateKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Prev = &prev
+	f.PrevExpiresAt = time.Now().Add(grace)
+	if err := s.save(&f, newKey, keyType); err != nil {
+		return nil, err
+	}
+	return newKey, nil
+}
+
+// PreviousPeerID returns the peer ID of the previous identity, if one is
+// still within its rotation grace period, so it can keep being announced on
+// bootstrap lists while peers migrate to the current ID.
+func (s *IdentityStore) PreviousPeerID() (peer.ID, bool, error) {
+	prevKey, _, ok, err := s.PreviousKey()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	id, err := peer.IDFromPrivateKey(prevKey)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to derive previous peer id: %w", err)
+	}
+	return id, true, nil
+}
+
+// PreviousKey returns the previous identity's private key and grace-period
+// expiry, if one is still within its rotation grace period. Callers that
+// need to keep the previous identity reachable (see GraceHost) use this
+// instead of PreviousPeerID, which only exposes the derived peer ID.
+func (s *IdentityStore) PreviousKey() (key crypto.PrivKey, expiresAt time.Time, ok bool, err error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to read identity file %s: %w", s.path, err)
+	}
+	var f identityFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to parse identity file %s: %w", s.path, err)
+	}
+	if f.Prev == nil || !time.Now().Before(f.PrevExpiresAt) {
+		return nil, time.Time{}, false, nil
+	}
+
+	prevKey, err := s.decodeRecord(*f.Prev)
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to decode previous key: %w", err)
+	}
+	return prevKey, f.PrevExpiresAt, true, nil
+}
+
+func (s *IdentityStore) save(f *identityFile, priv crypto.PrivKey, keyType KeyType) error {
+	rec, err := s.encodeRecord(priv, keyType)
+	if err != nil {
+		return err
+	}
+	f.Version = identityFileVersion
+	f.Current = rec
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal identity file: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write identity file %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *IdentityStore) encodeRecord(priv crypto.PrivKey, keyType KeyType) (identityRecord, error) {
+	raw, err := crypto.MarshalPrivateKey(priv)
+	if err != nil {
+		return identityRecord{}, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	rec := identityRecord{
+		KeyType:   keyType,
+		CreatedAt: time.Now(),
+	}
+
+	if s.passphrase == "" {
+		rec.KeyData = base64.StdEncoding.EncodeToString(raw)
+		return rec, nil
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return identityRecord{}, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return identityRecord{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	secretKey, err := deriveSecretboxKey(s.passphrase, salt)
+	if err != nil {
+		return identityRecord{}, err
+	}
+
+	sealed := secretbox.Seal(nil, raw, &nonce, secretKey)
+	rec.Encrypted = true
+	rec.KeyData = base64.StdEncoding.EncodeToString(sealed)
+	rec.Salt = base64.StdEncoding.EncodeToString(salt)
+	rec.Nonce = base64.StdEncoding.EncodeToString(nonce[:])
+	return rec, nil
+}
+
+func (s *IdentityStore) decodeRecord(rec identityRecord) (crypto.PrivKey, error) {
+	data, err := base64.StdEncoding.DecodeString(rec.KeyData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key data: %w", err)
+	}
+
+	if !rec.Encrypted {
+		return crypto.UnmarshalPrivateKey(data)
+	}
+
+	if s.passphrase == "" {
+		return nil, fmt.Errorf("identity is passphrase-encrypted but no passphrase was provided")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(rec.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+	nonceBytes, err := base64.StdEncoding.DecodeString(rec.Nonce)
+	if err != nil || len(nonceBytes) != 24 {
+		return nil, fmt.Errorf("failed to decode nonce: %w", err)
+	}
+	var nonce [24]byte
+	copy(nonce[:], nonceBytes)
+
+	secretKey, err := deriveSecretboxKey(s.passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := secretbox.Open(nil, data, &nonce, secretKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt identity: wrong passphrase or corrupt file")
+	}
+	return crypto.UnmarshalPrivateKey(raw)
+}
+
+func deriveSecretboxKey(passphrase string, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+func generateKey(keyType KeyType) (crypto.PrivKey, error) {
+	switch keyType {
+	case KeyTypeEd25519:
+		priv, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+		return priv, err
+	case KeyTypeSecp256k1:
+		priv, _, err := crypto.GenerateKeyPair(crypto.Secp256k1, -1)
+		return priv, err
+	case KeyTypeRSA:
+		priv, _, err := crypto.GenerateKeyPair(crypto.RSA, 2048)
+		return priv, err
+	default:
+		return nil, fmt.Errorf("unknown key type %q", keyType)
+	}
+}
+
+// generateIdentityCommand implements the "-generate-identity" subcommand.
+func generateIdentityCommand(args []string) {
+	fs := flag.NewFlagSet("generate-identity", flag.ExitOnError)
+	out := fs.String("out", "identity.json", "Path to write the generated identity to")
+	keyType := fs.String("type", string(KeyTypeEd25519), "Key type: ed25519, secp256k1, or rsa")
+	passphrase := fs.String("passphrase", "", "Optional passphrase to encrypt the key at rest")
+	fs.Parse(args)
+
+	store := NewIdentityStore(*out, *passphrase)
+	if _, err := store.LoadOrCreate(KeyType(*keyType)); err != nil {
+		log.Fatalf("Failed to generate identity: %v", err)
+	}
+	log.Printf("Generated %s identity: %s", *keyType, *out)
+}
+
+// rotateIdentityCommand implements the "-rotate-identity" subcommand.
+func rotateIdentityCommand(args []string) {
+	fs := flag.NewFlagSet("rotate-identity", flag.ExitOnError)
+	path := fs.String("identity", "identity.json", "Path to the identity keystore to rotate")
+	keyType := fs.String("type", string(KeyTypeEd25519), "Key type for the new key: ed25519, secp256k1, or rsa")
+	passphrase := fs.String("passphrase", "", "Passphrase protecting the identity keystore, if any")
+	grace := fs.Duration("grace", 24*time.Hour, "How long the previous peer ID stays announceable")
+	fs.Parse(args)
+
+	store := NewIdentityStore(*path, *passphrase)
+	if _, err := store.Rotate(KeyType(*keyType), *grace); err != nil {
+		log.Fatalf("Failed to rotate identity: %v", err)
+	}
+	log.Printf("Rotated %s identity: %s (previous peer ID announceable for %s)", *keyType, *path, *grace)
+}
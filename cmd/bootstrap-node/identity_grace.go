@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/host"
+)
+
+// graceTCPPortOffset is added to each TCP listen port to derive where the
+// previous identity's host binds, since it can't share a port with the
+// current identity's host. Operators migrating bootstrap lists need to
+// point at this port (same host, port+graceTCPPortOffset) until the old
+// identity's grace period expires.
+const graceTCPPortOffset = 1
+
+// graceListenAddrs derives listen addresses for the previous identity's
+// host from the current host's TCP listen addresses by bumping the port,
+// so the two hosts can run side by side without a bind conflict.
+func graceListenAddrs(tcpAddrs []string) ([]string, error) {
+	addrs := make([]string, 0, len(tcpAddrs))
+	for _, a := range tcpAddrs {
+		const prefix = "/tcp/"
+		idx := strings.Index(a, prefix)
+		if idx < 0 {
+			return nil, fmt.Errorf("listen address %q has no /tcp/ component", a)
+		}
+		portStart := idx + len(prefix)
+		port, err := strconv.Atoi(a[portStart:])
+		if err != nil {
+			return nil, fmt.Errorf("listen address %q has a non-numeric port: %w", a, err)
+		}
+		addrs = append(addrs, fmt.Sprintf("%s%d", a[:portStart], port+graceTCPPortOffset))
+	}
+	return addrs, nil
+}
+
+// GraceHost keeps a rotated-out identity reachable on the network for a
+// grace period, so peers that still have it on a bootstrap list can dial in
+// successfully while they migrate to the current identity. Since it can't
+// bind the same address as the current host, it listens on an alternate
+// port (see graceListenAddrs) that operators need to add to their bootstrap
+// lists for the duration of the migration. It closes its own host once
+// expiresAt passes.
+type GraceHost struct {
+	host   host.Host
+	cancel context.CancelFunc
+}
+
+// StartGraceHost brings up a minimal libp2p host under prevKey, listening
+// on listenAddrs, and schedules its own shutdown at expiresAt.
+func StartGraceHost(prevKey crypto.PrivKey, listenAddrs []string, expiresAt time.Time) (*GraceHost, error) {
+	h, err := libp2p.New(
+		libp2p.Identity(prevKey),
+		libp2p.ListenAddrStrings(listenAddrs...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start grace-period identity host: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	gh := &GraceHost{host: h, cancel: cancel}
+
+	go func() {
+		timer := time.NewTimer(time.Until(expiresAt))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			log.Printf("Previous identity %s grace period expired; shutting it down", h.ID())
+			h.Close()
+		case <-ctx.Done():
+		}
+	}()
+
+	log.Printf("Previous identity %s reachable on %v until %s; add these to bootstrap lists still using the old ID", h.ID(), h.Addrs(), expiresAt.Format(time.RFC3339))
+	return gh, nil
+}
+
+// Close stops the grace-period host immediately, regardless of expiresAt.
+func (gh *GraceHost) Close() error {
+	gh.cancel()
+	return gh.host.Close()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIdentityStoreEncryptedRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.json")
+	store := NewIdentityStore(path, "correct horse battery staple")
+
+	created, err := store.LoadOrCreate(KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+
+	loaded, _, err := NewIdentityStore(path, "correct horse battery staple").Load()
+	if err != nil {
+		t.Fatalf("Load with correct passphrase: %v", err)
+	}
+	if !created.Equals(loaded) {
+		t.Fatal("decrypted key does not match the key that was generated")
+	}
+
+	if _, _, err := NewIdentityStore(path, "wrong passphrase").Load(); err == nil {
+		t.Fatal("expected error loading with wrong passphrase, got nil")
+	}
+
+	if _, _, err := NewIdentityStore(path, "").Load(); err == nil {
+		t.Fatal("expected error loading an encrypted identity with no passphrase, got nil")
+	}
+}
+
+func TestIdentityStoreUnencryptedRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity.json")
+	store := NewIdentityStore(path, "")
+
+	created, err := store.LoadOrCreate(KeyTypeEd25519)
+	if err != nil {
+		t.Fatalf("LoadOrCreate: %v", err)
+	}
+
+	loaded, _, err := NewIdentityStore(path, "").Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !created.Equals(loaded) {
+		t.Fatal("decrypted key does not match the key that was generated")
+	}
+}
@@ -7,40 +7,58 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/libp2p/go-libp2p"
+	dht "github.com/libp2p/go-libp2p-kad-dht"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
-	dht "github.com/libp2p/go-libp2p-kad-dht"
 	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
 	"github.com/multiformats/go-multiaddr"
 )
 
 // BootstrapNode represents the libp2p bootstrap node
 type BootstrapNode struct {
-	host       host.Host
-	dht        *dht.IpfsDHT
-	ctx        context.Context
-	cancel     context.CancelFunc
-	listenAddr string
+	host        host.Host
+	dht         *dht.IpfsDHT
+	ctx         context.Context
+	cancel      context.CancelFunc
+	listenAddrs []string
+	startedAt   time.Time
+
+	pubsub         *pubsub.PubSub
+	heartbeatTopic *pubsub.Topic
+	heartbeatSub   *pubsub.Subscription
+
+	topicsMu sync.Mutex
+	topics   map[string]*pubsub.Topic
+
+	relay     *RelayService
+	discovery *DiscoveryService
 }
 
 // NewBootstrapNode creates a new bootstrap node instance
-func NewBootstrapNode(listenAddr string, privateKey crypto.PrivKey) (*BootstrapNode, error) {
+func NewBootstrapNode(cfg Config, privateKey crypto.PrivKey) (*BootstrapNode, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	hostOpts, err := buildHostOptions(cfg)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to build host options: %w", err)
+	}
+
 	// Create libp2p host
-	h, err := libp2p.New(
+	h, err := libp2p.New(append([]libp2p.Option{
 		libp2p.Identity(privateKey),
-		libp2p.ListenAddrStrings(listenAddr),
 		libp2p.Ping(true),
 		libp2p.EnableNATService(),
 		libp2p.EnableRelay(),
-	)
+	}, hostOpts...)...)
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to create libp2p host: %w", err)
@@ -62,13 +80,46 @@ func NewBootstrapNode(listenAddr string, privateKey crypto.PrivKey) (*BootstrapN
 		return nil, fmt.Errorf("failed to bootstrap DHT: %w", err)
 	}
 
-	return &BootstrapNode{
-		host:       h,
-		dht:        kadDHT,
-		ctx:        ctx,
-		cancel:     cancel,
-		listenAddr: listenAddr,
-	}, nil
+	bn := &BootstrapNode{
+		host:        h,
+		dht:         kadDHT,
+		ctx:         ctx,
+		cancel:      cancel,
+		listenAddrs: cfg.ListenAddrs,
+		topics:      make(map[string]*pubsub.Topic),
+	}
+
+	if err := bn.setupGossip(time.Now()); err != nil {
+		kadDHT.Close()
+		h.Close()
+		cancel()
+		return nil, fmt.Errorf("failed to set up gossip: %w", err)
+	}
+
+	if cfg.Relay.Enabled {
+		rs, err := NewRelayService(h, cfg.Relay)
+		if err != nil {
+			kadDHT.Close()
+			h.Close()
+			cancel()
+			return nil, fmt.Errorf("failed to start relay service: %w", err)
+		}
+		bn.relay = rs
+		log.Println("Circuit relay v2 service enabled")
+	}
+
+	if len(cfg.Rendezvous) > 0 {
+		ds := NewDiscoveryService(ctx, h, kadDHT, cfg.Rendezvous)
+		if err := ds.Start(); err != nil {
+			kadDHT.Close()
+			h.Close()
+			cancel()
+			return nil, fmt.Errorf("failed to start discovery service: %w", err)
+		}
+		bn.discovery = ds
+	}
+
+	return bn, nil
 }
 
 // Start begins listening for connections
@@ -86,10 +137,12 @@ func (bn *BootstrapNode) Start() error {
 	bn.host.Network().Notify(&network.NotifyBundle{
 		ConnectedF: func(n network.Network, conn network.Conn) {
 			log.Printf("New peer connected: %s", conn.RemotePeer())
+			connectsTotal.Inc()
 			bn.logPeerStats()
 		},
 		DisconnectedF: func(n network.Network, conn network.Conn) {
 			log.Printf("Peer disconnected: %s", conn.RemotePeer())
+			disconnectsTotal.Inc()
 			bn.logPeerStats()
 		},
 	})
@@ -118,12 +171,34 @@ func (bn *BootstrapNode) GetPeers() []peer.ID {
 
 // GetDHTStats returns DHT statistics
 func (bn *BootstrapNode) GetDHTStats() map[string]interface{} {
-	return map[string]interface{}{
+	stats := map[string]interface{}{
 		"routing_table_size": bn.dht.RoutingTable().Size(),
 		"peer_count":         len(bn.host.Network().Peers()),
 		"host_id":            bn.host.ID().String(),
 		"multiaddrs":         formatMultiaddrs(bn.host.Addrs(), bn.host.ID()),
 	}
+	if bn.relay != nil {
+		stats["relay"] = bn.GetRelayStats()
+	}
+	return stats
+}
+
+// GetRelayStats returns circuit relay v2 reservation and circuit usage, or
+// nil if the relay service is not enabled.
+func (bn *BootstrapNode) GetRelayStats() map[string]interface{} {
+	if bn.relay == nil {
+		return nil
+	}
+	return bn.relay.GetRelayStats()
+}
+
+// GetDiscoveredPeers returns peers found via rendezvous discovery, or nil if
+// no -rendezvous strings were configured.
+func (bn *BootstrapNode) GetDiscoveredPeers() []peer.AddrInfo {
+	if bn.discovery == nil {
+		return nil
+	}
+	return bn.discovery.GetDiscoveredPeers()
 }
 
 // formatMultiaddrs formats multiaddrs with peer ID
@@ -139,6 +214,21 @@ func formatMultiaddrs(addrs []multiaddr.Multiaddr, peerID peer.ID) []string {
 func (bn *BootstrapNode) Close() error {
 	log.Println("Shutting down bootstrap node...")
 
+	if bn.relay != nil {
+		if err := bn.relay.Close(); err != nil {
+			log.Printf("Error closing relay service: %v", err)
+		}
+	}
+
+	if bn.heartbeatSub != nil {
+		bn.heartbeatSub.Cancel()
+	}
+	if bn.heartbeatTopic != nil {
+		if err := bn.heartbeatTopic.Close(); err != nil {
+			log.Printf("Error closing heartbeat topic: %v", err)
+		}
+	}
+
 	if err := bn.dht.Close(); err != nil {
 		log.Printf("Error closing DHT: %v", err)
 	}
@@ -153,55 +243,142 @@ func (bn *BootstrapNode) Close() error {
 	return nil
 }
 
-// PeriodicStats logs statistics periodically
+// PeriodicStats refreshes the Prometheus gauges exposed by the admin API.
+// It used to log a full stats dump on every tick; that's now available
+// on-demand via /dht/stats and /metrics instead.
 func (bn *BootstrapNode) PeriodicStats(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	lastRoutingTableSize := bn.dht.RoutingTable().Size()
 	for {
 		select {
 		case <-ticker.C:
-			stats := bn.GetDHTStats()
-			log.Printf("Stats: %+v", stats)
+			size := bn.dht.RoutingTable().Size()
+			routingTableSize.Set(float64(size))
+			if delta := size - lastRoutingTableSize; delta != 0 {
+				routingTableChurn.Add(float64(abs(delta)))
+			}
+			lastRoutingTableSize = size
+
+			bn.updateProtocolStreamCounts()
 		case <-bn.ctx.Done():
 			return
 		}
 	}
 }
 
+// updateProtocolStreamCounts recomputes the per-protocol open stream gauge
+// across all connected peers.
+func (bn *BootstrapNode) updateProtocolStreamCounts() {
+	counts := make(map[string]int)
+	for _, conn := range bn.host.Network().Conns() {
+		for _, stream := range conn.GetStreams() {
+			counts[string(stream.Protocol())]++
+		}
+	}
+	protocolStreamCount.Reset()
+	for proto, n := range counts {
+		protocolStreamCount.WithLabelValues(proto).Set(float64(n))
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "-generate-psk" {
+		generatePSKCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "-generate-identity" {
+		generateIdentityCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "-rotate-identity" {
+		rotateIdentityCommand(os.Args[2:])
+		return
+	}
+
 	// Command line flags
-	listenAddr := flag.String("listen", "/ip4/0.0.0.0/tcp/4001", "Multiaddr to listen on")
-	identityFile := flag.String("identity", "", "Path to private key file (optional)")
-	statsInterval := flag.Duration("stats-interval", 30*time.Second, "Interval for logging stats")
+	defaults := DefaultConfig()
+	var listenAddrs stringSliceFlag
+	flag.Var(&listenAddrs, "listen", "Multiaddr to listen on (repeatable)")
+	quicPort := flag.Int("quic-port", defaults.QUICPort, "UDP port to listen on for QUIC")
+	disableTCP := flag.Bool("disable-tcp", false, "Disable the TCP transport and listen over QUIC only")
+	connLow := flag.Int("conn-low", defaults.ConnLow, "Connection manager low watermark")
+	connHigh := flag.Int("conn-high", defaults.ConnHigh, "Connection manager high watermark")
+	connGrace := flag.Duration("conn-grace", defaults.ConnGrace, "Connection manager grace period before trimming")
+	relayMode := flag.Bool("relay-mode", false, "Run a circuit relay v2 service for NAT-blocked clients")
+	relayMaxReservations := flag.Int("relay-max-reservations", defaults.Relay.MaxReservations, "Max concurrent relay reservations")
+	relayMaxCircuits := flag.Int("relay-max-circuits", defaults.Relay.MaxCircuits, "Max concurrent relayed circuits")
+	relayMaxReservPerPeer := flag.Int("relay-max-reservations-per-peer", defaults.Relay.MaxReservPerPeer, "Max reservations held by a single peer")
+	relayReservationTTL := flag.Duration("relay-reservation-ttl", defaults.Relay.ReservationTTL, "Relay reservation time-to-live")
+	relayDataLimit := flag.Int64("relay-data-limit", defaults.Relay.DataLimit, "Max bytes relayed per circuit")
+	pskFile := flag.String("psk-file", "", "Path to a pre-shared network key; enables a private libp2p network")
+	allowPeersFile := flag.String("allow-peers", "", "Path to a file of allow-listed peer IDs/CIDRs/multiaddrs")
+	denyPeersFile := flag.String("deny-peers", "", "Path to a file of deny-listed peer IDs/CIDRs/multiaddrs")
+	identityFile := flag.String("identity", "identity.json", "Path to the node identity keystore file")
+	identityType := flag.String("identity-type", string(KeyTypeEd25519), "Key type to generate if the identity file doesn't exist: ed25519, secp256k1, or rsa")
+	identityPassphrase := flag.String("identity-passphrase", "", "Optional passphrase protecting the identity keystore")
+	statsInterval := flag.Duration("stats-interval", 30*time.Second, "Interval for refreshing Prometheus gauges")
+	adminListen := flag.String("admin-listen", "127.0.0.1:8080", "Address for the HTTP/JSON and Prometheus admin API (empty to disable); bind to a non-loopback address only behind a trusted network or with -admin-token set")
+	adminToken := flag.String("admin-token", "", "Bearer token required on the mutating /connect and /disconnect admin routes (leave empty only if -admin-listen is loopback-only)")
+	var rendezvous stringSliceFlag
+	flag.Var(&rendezvous, "rendezvous", "Rendezvous string to advertise and discover peers under (repeatable)")
 	flag.Parse()
 
-	// Generate or load private key
-	var privateKey crypto.PrivKey
-	var err error
+	if len(listenAddrs) == 0 {
+		listenAddrs = defaults.ListenAddrs
+	}
+	cfg := Config{
+		ListenAddrs: listenAddrs,
+		QUICPort:    *quicPort,
+		DisableTCP:  *disableTCP,
+		ConnLow:     *connLow,
+		ConnHigh:    *connHigh,
+		ConnGrace:   *connGrace,
+		Relay: RelayConfig{
+			Enabled:          *relayMode,
+			MaxReservations:  *relayMaxReservations,
+			MaxCircuits:      *relayMaxCircuits,
+			MaxReservPerPeer: *relayMaxReservPerPeer,
+			ReservationTTL:   *relayReservationTTL,
+			DataLimit:        *relayDataLimit,
+		},
+		PSKFile:        *pskFile,
+		AllowPeersFile: *allowPeersFile,
+		DenyPeersFile:  *denyPeersFile,
+		Rendezvous:     rendezvous,
+	}
+
+	// Load or create the node identity keystore
+	identityStore := NewIdentityStore(*identityFile, *identityPassphrase)
+	privateKey, err := identityStore.LoadOrCreate(KeyType(*identityType))
+	if err != nil {
+		log.Fatalf("Failed to load or create identity: %v", err)
+	}
+	log.Printf("Using identity keystore: %s", *identityFile)
 
-	if *identityFile != "" {
-		// Load private key from file
-		keyData, err := os.ReadFile(*identityFile)
-		if err != nil {
-			log.Fatalf("Failed to read identity file: %v", err)
-		}
-		privateKey, err = crypto.UnmarshalPrivateKey(keyData)
-		if err != nil {
-			log.Fatalf("Failed to unmarshal private key: %v", err)
-		}
-		log.Printf("Loaded identity from file: %s", *identityFile)
-	} else {
-		// Generate new key
-		privateKey, _, err = crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	var graceHost *GraceHost
+	if prevKey, expiresAt, ok, err := identityStore.PreviousKey(); err != nil {
+		log.Printf("Failed to check for a previous identity: %v", err)
+	} else if ok {
+		graceAddrs, err := graceListenAddrs(listenAddrs)
 		if err != nil {
-			log.Fatalf("Failed to generate key pair: %v", err)
+			log.Printf("Failed to derive listen addresses for the previous identity: %v", err)
+		} else if graceHost, err = StartGraceHost(prevKey, graceAddrs, expiresAt); err != nil {
+			log.Printf("Failed to keep the previous identity reachable: %v", err)
+			graceHost = nil
 		}
-		log.Println("Generated new Ed25519 identity")
 	}
 
 	// Create bootstrap node
-	node, err := NewBootstrapNode(*listenAddr, privateKey)
+	node, err := NewBootstrapNode(cfg, privateKey)
 	if err != nil {
 		log.Fatalf("Failed to create bootstrap node: %v", err)
 	}
@@ -211,9 +388,15 @@ func main() {
 		log.Fatalf("Failed to start bootstrap node: %v", err)
 	}
 
-	// Start periodic stats logging
+	// Start periodic gauge refresh
 	go node.PeriodicStats(*statsInterval)
 
+	var admin *AdminServer
+	if *adminListen != "" {
+		admin = NewAdminServer(node, *adminListen, *adminToken)
+		admin.Start()
+	}
+
 	// Handle shutdown gracefully
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -221,6 +404,20 @@ func main() {
 	<-sigChan
 	log.Println("Received shutdown signal")
 
+	if admin != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := admin.Close(shutdownCtx); err != nil {
+			log.Printf("Error shutting down admin server: %v", err)
+		}
+		cancel()
+	}
+
+	if graceHost != nil {
+		if err := graceHost.Close(); err != nil {
+			log.Printf("Error shutting down previous identity host: %v", err)
+		}
+	}
+
 	if err := node.Close(); err != nil {
 		log.Fatalf("Error during shutdown: %v", err)
 	}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	connectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openclaw_connects_total",
+		Help: "Number of inbound and outbound connections established.",
+	})
+	disconnectsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openclaw_disconnects_total",
+		Help: "Number of peer disconnections observed.",
+	})
+	dhtQueriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openclaw_dht_queries_total",
+		Help: "Number of DHT queries served via the admin API.",
+	})
+	routingTableSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "openclaw_dht_routing_table_size",
+		Help: "Current number of peers in the DHT routing table.",
+	})
+	routingTableChurn = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "openclaw_dht_routing_table_churn_total",
+		Help: "Cumulative absolute change in routing table size between samples.",
+	})
+	protocolStreamCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openclaw_protocol_streams",
+		Help: "Number of open streams per protocol ID.",
+	}, []string{"protocol"})
+)
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/libp2p/go-libp2p/core/pnet"
+)
+
+// pskSwarmKeyHeader is the on-disk format libp2p's reference implementations
+// use for pre-shared network keys (ipfs' "swarm.key").
+const pskSwarmKeyHeader = "/key/swarm/psk/1.0.0/\n/base16/\n"
+
+// GeneratePSK creates a new random 32-byte pre-shared key and writes it to
+// path in the standard swarm.key format, for use with -generate-psk.
+func GeneratePSK(path string) error {
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return fmt.Errorf("failed to generate PSK: %w", err)
+	}
+
+	contents := pskSwarmKeyHeader + hex.EncodeToString(key[:]) + "\n"
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		return fmt.Errorf("failed to write PSK file %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadPSK reads and decodes a pre-shared key written by GeneratePSK.
+func LoadPSK(path string) (pnet.PSK, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PSK file %s: %w", path, err)
+	}
+
+	psk, err := pnet.DecodeV1PSK(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PSK file %s: %w", path, err)
+	}
+	return psk, nil
+}
+
+// generatePSKCommand implements the "-generate-psk <path>" subcommand.
+func generatePSKCommand(args []string) {
+	fs := flag.NewFlagSet("generate-psk", flag.ExitOnError)
+	out := fs.String("out", "swarm.key", "Path to write the generated pre-shared key to")
+	fs.Parse(args)
+
+	if err := GeneratePSK(*out); err != nil {
+		log.Fatalf("Failed to generate PSK: %v", err)
+	}
+	log.Printf("Generated pre-shared network key: %s", *out)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	pbv2 "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/pb"
+	relayv2 "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/relay"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// RelayConfig holds the flags controlling circuitv2 relay.Resources when
+// -relay-mode is enabled.
+type RelayConfig struct {
+	Enabled          bool
+	MaxReservations  int
+	MaxCircuits      int
+	MaxReservPerPeer int
+	ReservationTTL   time.Duration
+	DataLimit        int64
+}
+
+// DefaultRelayConfig returns the flag defaults used by main().
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		MaxReservations:  256,
+		MaxCircuits:      16,
+		MaxReservPerPeer: 4,
+		ReservationTTL:   time.Hour,
+		DataLimit:        1 << 20, // 1 MiB per circuit
+	}
+}
+
+// RelayService runs a circuitv2 relay and tracks reservation/circuit usage
+// so it can be surfaced through GetRelayStats and the admin API.
+type RelayService struct {
+	relay *relayv2.Relay
+
+	reservationTTL time.Duration
+
+	mu             sync.Mutex
+	activeReserv   int
+	activeCircuits int
+	bytesRelayed   int64
+	// reservationExpiry tracks, per peer, when its current reservation
+	// expires. relayv2.MetricsTracer's hooks don't carry a peer ID, so this
+	// is populated from AllowReserve (the ACLFilter hook, which does) rather
+	// than ReservationAllowed/ReservationClosed; entries are pruned lazily
+	// in GetRelayStats.
+	reservationExpiry map[peer.ID]time.Time
+}
+
+// NewRelayService starts a circuitv2 relay on h, constrained by cfg.
+func NewRelayService(h host.Host, cfg RelayConfig) (*RelayService, error) {
+	rs := &RelayService{
+		reservationTTL:    cfg.ReservationTTL,
+		reservationExpiry: make(map[peer.ID]time.Time),
+	}
+
+	resources := relayv2.DefaultResources()
+	resources.MaxReservations = cfg.MaxReservations
+	resources.MaxCircuits = cfg.MaxCircuits
+	resources.MaxReservationsPerPeer = cfg.MaxReservPerPeer
+	resources.ReservationTTL = cfg.ReservationTTL
+	resources.Limit = &relayv2.RelayLimit{
+		Duration: 2 * time.Minute,
+		Data:     cfg.DataLimit,
+	}
+
+	r, err := relayv2.New(h,
+		relayv2.WithResources(resources),
+		relayv2.WithMetricsTracer(rs),
+		relayv2.WithACL(rs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start relay service: %w", err)
+	}
+	rs.relay = r
+
+	return rs, nil
+}
+
+// Close shuts down the relay service.
+func (rs *RelayService) Close() error {
+	return rs.relay.Close()
+}
+
+// GetRelayStats returns a snapshot of reservation and circuit usage.
+func (rs *RelayService) GetRelayStats() map[string]interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	now := time.Now()
+	perPeer := make(map[string]int, len(rs.reservationExpiry))
+	for id, expire := range rs.reservationExpiry {
+		if expire.Before(now) {
+			delete(rs.reservationExpiry, id)
+			continue
+		}
+		perPeer[id.String()] = 1
+	}
+
+	return map[string]interface{}{
+		"active_reservations":  rs.activeReserv,
+		"active_circuits":      rs.activeCircuits,
+		"bytes_relayed":        rs.bytesRelayed,
+		"reservations_by_peer": perPeer,
+	}
+}
+
+// AllowReserve and AllowConnect implement relayv2.ACLFilter. The relay
+// doesn't expose its reservation table, so this is the only hook that
+// learns a reservation's peer ID; it's used purely for per-peer accounting
+// here and always permits, leaving enforcement to relay.Resources.
+func (rs *RelayService) AllowReserve(p peer.ID, a ma.Multiaddr) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.reservationExpiry[p] = time.Now().Add(rs.reservationTTL)
+	return true
+}
+
+func (rs *RelayService) AllowConnect(src peer.ID, srcAddr ma.Multiaddr, dest peer.ID) bool {
+	return true
+}
+
+// The methods below implement relayv2.MetricsTracer so the relay package
+// reports reservation/circuit lifecycle events directly into our stats.
+
+func (rs *RelayService) RelayStatus(enabled bool) {
+	log.Printf("Relay status changed: enabled=%v", enabled)
+}
+
+func (rs *RelayService) ReservationAllowed(isRenewal bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if !isRenewal {
+		rs.activeReserv++
+	}
+	log.Printf("Relay reservation granted (renewal=%v), active=%d", isRenewal, rs.activeReserv)
+}
+
+func (rs *RelayService) ReservationClosed(cnt int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.activeReserv -= cnt
+	if rs.activeReserv < 0 {
+		rs.activeReserv = 0
+	}
+	log.Printf("Relay reservation(s) expired: %d, active=%d", cnt, rs.activeReserv)
+}
+
+// ConnectionRequestHandled and ReservationRequestHandled are part of
+// relayv2.MetricsTracer but only carry protocol-level accept/reject status,
+// which GetRelayStats doesn't currently break out; satisfy the interface
+// without tracking them separately.
+func (rs *RelayService) ConnectionRequestHandled(status pbv2.Status) {}
+
+func (rs *RelayService) ReservationRequestHandled(status pbv2.Status) {}
+
+func (rs *RelayService) ConnectionOpened() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.activeCircuits++
+}
+
+func (rs *RelayService) ConnectionClosed(time.Duration) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.activeCircuits--
+	if rs.activeCircuits < 0 {
+		rs.activeCircuits = 0
+	}
+}
+
+func (rs *RelayService) BytesTransferred(num int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.bytesRelayed += int64(num)
+}
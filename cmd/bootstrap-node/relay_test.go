@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+func mustRelayPeerID(t *testing.T) peer.ID {
+	t.Helper()
+	_, pub, err := crypto.GenerateEd25519Key(nil)
+	if err != nil {
+		t.Fatalf("GenerateEd25519Key: %v", err)
+	}
+	id, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatalf("IDFromPublicKey: %v", err)
+	}
+	return id
+}
+
+func newTestRelayService(ttl time.Duration) *RelayService {
+	return &RelayService{
+		reservationTTL:    ttl,
+		reservationExpiry: make(map[peer.ID]time.Time),
+	}
+}
+
+func TestGetRelayStatsAggregateCounters(t *testing.T) {
+	rs := newTestRelayService(time.Hour)
+
+	rs.ReservationAllowed(false)
+	rs.ReservationAllowed(false)
+	rs.ConnectionOpened()
+	rs.BytesTransferred(1024)
+
+	stats := rs.GetRelayStats()
+	if stats["active_reservations"] != 2 {
+		t.Fatalf("active_reservations = %v, want 2", stats["active_reservations"])
+	}
+	if stats["active_circuits"] != 1 {
+		t.Fatalf("active_circuits = %v, want 1", stats["active_circuits"])
+	}
+	if stats["bytes_relayed"] != int64(1024) {
+		t.Fatalf("bytes_relayed = %v, want 1024", stats["bytes_relayed"])
+	}
+
+	rs.ReservationClosed(1)
+	if got := rs.GetRelayStats()["active_reservations"]; got != 1 {
+		t.Fatalf("active_reservations after close = %v, want 1", got)
+	}
+}
+
+func TestGetRelayStatsPerPeerReflectsAllowReserve(t *testing.T) {
+	rs := newTestRelayService(time.Hour)
+	p1 := mustRelayPeerID(t)
+	p2 := mustRelayPeerID(t)
+
+	if !rs.AllowReserve(p1, nil) || !rs.AllowReserve(p2, nil) {
+		t.Fatal("AllowReserve unexpectedly denied a reservation")
+	}
+
+	perPeer, ok := rs.GetRelayStats()["reservations_by_peer"].(map[string]int)
+	if !ok {
+		t.Fatalf("reservations_by_peer has unexpected type: %T", rs.GetRelayStats()["reservations_by_peer"])
+	}
+	if perPeer[p1.String()] != 1 || perPeer[p2.String()] != 1 {
+		t.Fatalf("reservations_by_peer = %v, want both peers present", perPeer)
+	}
+}
+
+func TestGetRelayStatsPerPeerPrunesExpired(t *testing.T) {
+	rs := newTestRelayService(-time.Second) // reservations expire immediately
+	p := mustRelayPeerID(t)
+
+	rs.AllowReserve(p, nil)
+
+	perPeer := rs.GetRelayStats()["reservations_by_peer"].(map[string]int)
+	if len(perPeer) != 0 {
+		t.Fatalf("reservations_by_peer = %v, want expired entry pruned", perPeer)
+	}
+
+	rs.mu.Lock()
+	remaining := len(rs.reservationExpiry)
+	rs.mu.Unlock()
+	if remaining != 0 {
+		t.Fatalf("reservationExpiry still has %d entries after a stats read pruned them", remaining)
+	}
+}
+
+func TestAllowConnectAlwaysPermits(t *testing.T) {
+	rs := newTestRelayService(time.Hour)
+	if !rs.AllowConnect(mustRelayPeerID(t), nil, mustRelayPeerID(t)) {
+		t.Fatal("AllowConnect denied a connection; ACL is accounting-only and should always permit")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p/p2p/muxer/yamux"
+	connmgr "github.com/libp2p/go-libp2p/p2p/net/connmgr"
+	"github.com/libp2p/go-libp2p/p2p/security/noise"
+	tls "github.com/libp2p/go-libp2p/p2p/security/tls"
+	quic "github.com/libp2p/go-libp2p/p2p/transport/quic"
+	tcp "github.com/libp2p/go-libp2p/p2p/transport/tcp"
+)
+
+// Config holds the settings NewBootstrapNode needs to assemble a libp2p
+// host. It grows as new subsystems (relay, PSK, identity, discovery) pick up
+// their own flags.
+type Config struct {
+	ListenAddrs []string
+	QUICPort    int
+	DisableTCP  bool
+
+	ConnLow   int
+	ConnHigh  int
+	ConnGrace time.Duration
+
+	Relay RelayConfig
+
+	PSKFile        string
+	AllowPeersFile string
+	DenyPeersFile  string
+
+	Rendezvous []string
+}
+
+// DefaultConfig returns the flag defaults used by main().
+func DefaultConfig() Config {
+	return Config{
+		ListenAddrs: []string{"/ip4/0.0.0.0/tcp/4001"},
+		QUICPort:    4001,
+		ConnLow:     256,
+		ConnHigh:    768,
+		ConnGrace:   30 * time.Second,
+		Relay:       DefaultRelayConfig(),
+	}
+}
+
+// quicListenAddrs derives QUIC multiaddrs from the TCP listen addresses by
+// swapping the transport suffix, so "-listen" stays the single source of
+// truth for bind IPs/interfaces.
+func quicListenAddrs(tcpAddrs []string, quicPort int) []string {
+	addrs := make([]string, 0, len(tcpAddrs))
+	for _, a := range tcpAddrs {
+		addrs = append(addrs, fmt.Sprintf("%s/udp/%d/quic", stripTCPPort(a), quicPort))
+	}
+	return addrs
+}
+
+// stripTCPPort removes the trailing "/tcp/<port>" component of a multiaddr
+// string, leaving just the host portion (e.g. "/ip4/0.0.0.0").
+func stripTCPPort(addr string) string {
+	const suffix = "/tcp/"
+	if idx := indexOf(addr, suffix); idx >= 0 {
+		return addr[:idx]
+	}
+	return addr
+}
+
+func indexOf(s, substr string) int {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return i
+		}
+	}
+	return -1
+}
+
+// buildHostOptions assembles the libp2p.Option set for transports,
+// security, stream muxing, and connection-manager tuning described by cfg.
+func buildHostOptions(cfg Config) ([]libp2p.Option, error) {
+	listenAddrs := append([]string{}, cfg.ListenAddrs...)
+	// mplex is deliberately not offered: it was dropped from go-libp2p's own
+	// default muxer set (and removed from this module's tree entirely) after
+	// being superseded by yamux, which is flow-controlled and actively
+	// maintained.
+	opts := []libp2p.Option{
+		libp2p.Security(noise.ID, noise.New),
+		libp2p.Security(tls.ID, tls.New),
+		libp2p.Muxer("/yamux/1.0.0", yamux.DefaultTransport),
+		libp2p.Transport(quic.NewTransport),
+	}
+
+	// Specifying any libp2p.Transport option suppresses all of libp2p's
+	// default transports, so TCP must be re-added explicitly or the host
+	// ends up QUIC-only despite -disable-tcp being false.
+	if cfg.DisableTCP {
+		listenAddrs = quicListenAddrs(cfg.ListenAddrs, cfg.QUICPort)
+	} else {
+		opts = append(opts, libp2p.Transport(tcp.NewTCPTransport))
+		listenAddrs = append(listenAddrs, quicListenAddrs(cfg.ListenAddrs, cfg.QUICPort)...)
+	}
+	opts = append(opts, libp2p.ListenAddrStrings(listenAddrs...))
+
+	cm, err := connmgr.NewConnManager(
+		cfg.ConnLow,
+		cfg.ConnHigh,
+		connmgr.WithGracePeriod(cfg.ConnGrace),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection manager: %w", err)
+	}
+	opts = append(opts, libp2p.ConnectionManager(cm))
+
+	if cfg.PSKFile != "" {
+		psk, err := LoadPSK(cfg.PSKFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, libp2p.PrivateNetwork(psk))
+	}
+
+	if cfg.AllowPeersFile != "" || cfg.DenyPeersFile != "" {
+		var allow, deny *PeerList
+		var err error
+		if cfg.AllowPeersFile != "" {
+			if allow, err = LoadPeerList(cfg.AllowPeersFile); err != nil {
+				return nil, err
+			}
+		}
+		if cfg.DenyPeersFile != "" {
+			if deny, err = LoadPeerList(cfg.DenyPeersFile); err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, libp2p.ConnectionGater(NewPeerGater(allow, deny)))
+	}
+
+	return opts, nil
+}